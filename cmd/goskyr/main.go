@@ -0,0 +1,118 @@
+// Command goskyr runs the scrapers configured in a YAML config file and
+// writes the extracted items to the configured output.Writer. By default
+// it scrapes every scraper once and exits; with -schedule it instead runs
+// each scraper on its own ScrapeInterval and serves the /targets and
+// /-/reload admin endpoints until interrupted.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/jakopako/goskyr/logger"
+	"github.com/jakopako/goskyr/output"
+	"github.com/jakopako/goskyr/scheduler"
+	"github.com/jakopako/goskyr/scraper"
+)
+
+func main() {
+	configPath := flag.String("config", "config.yml", "path to the scraper config file")
+	loggerFile := flag.String("logger", "", "path to write logs to; overrides the global.logger.file config value")
+	schedule := flag.Bool("schedule", false, "run continuously, scheduling each scraper on its own scrape_interval instead of scraping once and exiting")
+	adminAddr := flag.String("admin-addr", ":9090", "address the /targets and /-/reload admin endpoints listen on when -schedule is set")
+	flag.Parse()
+
+	cfg, err := scraper.NewConfig(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "goskyr: failed to load config %s: %v\n", *configPath, err)
+		os.Exit(1)
+	}
+	if *loggerFile != "" {
+		cfg.Global.Logger.File = *loggerFile
+	}
+
+	log, err := logger.New(cfg.Global.Logger)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "goskyr: failed to set up logger: %v\n", err)
+		os.Exit(1)
+	}
+
+	writer, err := output.New(&cfg.Writer, log)
+	if err != nil {
+		log.Error("failed to set up writer", "error", err)
+		os.Exit(1)
+	}
+
+	if *schedule {
+		runScheduled(cfg, log, writer, *configPath, *adminAddr)
+		return
+	}
+	runOnce(cfg, log, writer)
+}
+
+// runOnce scrapes every configured scraper a single time and exits,
+// preserving goskyr's original non-scheduled behaviour.
+func runOnce(cfg *scraper.Config, log *slog.Logger, writer output.Writer) {
+	ctx := context.Background()
+	items, runErr := scheduler.RunOnce(ctx, cfg, log)
+	if runErr != nil {
+		log.Error("scrape run finished with errors", "error", runErr)
+	}
+
+	itemCh := make(chan map[string]interface{})
+	go func() {
+		defer close(itemCh)
+		for _, item := range items {
+			itemCh <- item
+		}
+	}()
+	if err := writer.Write(ctx, itemCh); err != nil {
+		log.Error("failed to write items", "error", err)
+		os.Exit(1)
+	}
+
+	if runErr != nil {
+		os.Exit(1)
+	}
+}
+
+// runScheduled starts a scheduler.TargetPool against cfg.Scrapers, serves
+// its admin endpoints on adminAddr, and feeds every item it emits to
+// writer until the process receives SIGINT/SIGTERM.
+func runScheduled(cfg *scraper.Config, log *slog.Logger, writer output.Writer, configPath, adminAddr string) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	pool := scheduler.NewTargetPool(&cfg.Global, log)
+	if err := pool.Sync(cfg.Scrapers); err != nil {
+		log.Error("failed to start targets", "error", err)
+		os.Exit(1)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/targets", pool.TargetsHandler)
+	mux.HandleFunc("/-/reload", pool.ReloadHandler(configPath))
+	server := &http.Server{Addr: adminAddr, Handler: mux}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Error("admin server exited", "error", err)
+		}
+	}()
+	log.Info("scheduler running", "admin-addr", adminAddr)
+
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+
+	if err := writer.Write(ctx, pool.Items()); err != nil && ctx.Err() == nil {
+		log.Error("failed to write items", "error", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,32 @@
+package discovery
+
+import (
+	"context"
+
+	"github.com/jakopako/goskyr/fetch"
+)
+
+// fetchWithContext runs fetcher.Fetch in its own goroutine and returns as
+// soon as either it completes or ctx is done. fetch.Fetcher has no
+// cancellation hook of its own, so a hung Fetch call can't be aborted
+// outright, but this still lets a caller's context (a scraper's
+// ScrapeTimeout, or a scheduler stopping a target) interrupt a
+// sitemap.xml or robots.txt fetch promptly instead of blocking on it
+// indefinitely.
+func fetchWithContext(ctx context.Context, fetcher fetch.Fetcher, url string) (string, error) {
+	type result struct {
+		res string
+		err error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		res, err := fetcher.Fetch(url)
+		ch <- result{res, err}
+	}()
+	select {
+	case r := <-ch:
+		return r.res, r.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
@@ -0,0 +1,161 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/jakopako/goskyr/fetch"
+)
+
+type robotsGroup struct {
+	userAgents []string
+	disallow   []string
+	allow      []string
+}
+
+// RobotsCache fetches and caches robots.txt per host so that repeated
+// Allowed calls for the same host, across DynamicFetcher and
+// StaticFetcher alike, only hit the network once.
+type RobotsCache struct {
+	mu     sync.Mutex
+	byHost map[string][]robotsGroup
+}
+
+// NewRobotsCache returns an empty, ready to use RobotsCache.
+func NewRobotsCache() *RobotsCache {
+	return &RobotsCache{byHost: map[string][]robotsGroup{}}
+}
+
+// Allowed reports whether userAgent may fetch rawURL according to the
+// robots.txt of rawURL's host, fetching and caching it on first use. ctx
+// bounds that fetch so a hanging robots.txt can't block the caller
+// forever.
+func (c *RobotsCache) Allowed(ctx context.Context, fetcher fetch.Fetcher, userAgent, rawURL string) (bool, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false, fmt.Errorf("discovery: failed to parse url %s: %w", rawURL, err)
+	}
+
+	groups, err := c.groupsFor(ctx, fetcher, u)
+	if err != nil {
+		return false, err
+	}
+
+	return allowed(groups, userAgent, u.Path), nil
+}
+
+func (c *RobotsCache) groupsFor(ctx context.Context, fetcher fetch.Fetcher, u *url.URL) ([]robotsGroup, error) {
+	host := u.Scheme + "://" + u.Host
+
+	c.mu.Lock()
+	groups, found := c.byHost[host]
+	c.mu.Unlock()
+	if found {
+		return groups, nil
+	}
+
+	res, err := fetchWithContext(ctx, fetcher, host+"/robots.txt")
+	if err != nil {
+		// A missing or unreachable robots.txt means everything is allowed.
+		groups = nil
+	} else {
+		groups = parseRobots(res)
+	}
+
+	c.mu.Lock()
+	c.byHost[host] = groups
+	c.mu.Unlock()
+	return groups, nil
+}
+
+func parseRobots(body string) []robotsGroup {
+	var groups []robotsGroup
+	var current *robotsGroup
+	startedRules := false
+
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		if line == "" {
+			continue
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		field := strings.ToLower(strings.TrimSpace(parts[0]))
+		value := strings.TrimSpace(parts[1])
+
+		switch field {
+		case "user-agent":
+			if current == nil || startedRules {
+				groups = append(groups, robotsGroup{})
+				current = &groups[len(groups)-1]
+				startedRules = false
+			}
+			current.userAgents = append(current.userAgents, strings.ToLower(value))
+		case "disallow":
+			if current == nil {
+				continue
+			}
+			startedRules = true
+			if value != "" {
+				current.disallow = append(current.disallow, value)
+			}
+		case "allow":
+			if current == nil {
+				continue
+			}
+			startedRules = true
+			if value != "" {
+				current.allow = append(current.allow, value)
+			}
+		}
+	}
+	return groups
+}
+
+// allowed picks the first group naming an agent contained in userAgent
+// (falling back to "*") and returns false if path is covered by a Disallow rule
+// that isn't overridden by a longer, more specific Allow rule.
+func allowed(groups []robotsGroup, userAgent, path string) bool {
+	group := matchGroup(groups, strings.ToLower(userAgent))
+	if group == nil {
+		return true
+	}
+
+	bestMatch := -1
+	isAllowed := true
+	for _, d := range group.disallow {
+		if strings.HasPrefix(path, d) && len(d) > bestMatch {
+			bestMatch = len(d)
+			isAllowed = false
+		}
+	}
+	for _, a := range group.allow {
+		if strings.HasPrefix(path, a) && len(a) > bestMatch {
+			bestMatch = len(a)
+			isAllowed = true
+		}
+	}
+	return isAllowed
+}
+
+func matchGroup(groups []robotsGroup, userAgent string) *robotsGroup {
+	var wildcard *robotsGroup
+	for i, g := range groups {
+		for _, ua := range g.userAgents {
+			if ua == "*" {
+				wildcard = &groups[i]
+			} else if strings.Contains(userAgent, ua) {
+				return &groups[i]
+			}
+		}
+	}
+	return wildcard
+}
@@ -0,0 +1,66 @@
+package discovery
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRobotsCache_Allowed(t *testing.T) {
+	tests := []struct {
+		name      string
+		userAgent string
+		url       string
+		want      bool
+	}{
+		{name: "disallowed path for default group", userAgent: "goskyr", url: "https://example.com/private/secret", want: false},
+		{name: "allow overrides the shorter disallow", userAgent: "goskyr", url: "https://example.com/private/public-notice", want: true},
+		{name: "unrelated path is allowed", userAgent: "goskyr", url: "https://example.com/events", want: true},
+		{name: "named group disallows everything", userAgent: "nosy-bot", url: "https://example.com/events", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetcher := newFakeFetcher(map[string]string{
+				"https://example.com/robots.txt": mustReadFixture(t, "robots.txt"),
+			})
+			cache := NewRobotsCache()
+			got, err := cache.Allowed(context.Background(), fetcher, tt.userAgent, tt.url)
+			if err != nil {
+				t.Fatalf("Allowed() returned error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Allowed(%q, %q) = %v, want %v", tt.userAgent, tt.url, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRobotsCache_CachesPerHost(t *testing.T) {
+	fetcher := newFakeFetcher(map[string]string{
+		"https://example.com/robots.txt": mustReadFixture(t, "robots.txt"),
+	})
+	cache := NewRobotsCache()
+
+	for i := 0; i < 3; i++ {
+		if _, err := cache.Allowed(context.Background(), fetcher, "goskyr", "https://example.com/events"); err != nil {
+			t.Fatalf("Allowed() returned error: %v", err)
+		}
+	}
+
+	if got := fetcher.calls["https://example.com/robots.txt"]; got != 1 {
+		t.Errorf("robots.txt fetched %d times, want 1", got)
+	}
+}
+
+func TestRobotsCache_MissingRobotsAllowsEverything(t *testing.T) {
+	fetcher := newFakeFetcher(map[string]string{})
+	cache := NewRobotsCache()
+
+	got, err := cache.Allowed(context.Background(), fetcher, "goskyr", "https://example.com/private/secret")
+	if err != nil {
+		t.Fatalf("Allowed() returned error: %v", err)
+	}
+	if !got {
+		t.Errorf("Allowed() = false, want true when robots.txt is missing")
+	}
+}
@@ -0,0 +1,118 @@
+// Package discovery implements the optional crawl-discovery features a
+// Scraper can opt into: seeding extra entry pages from a site's
+// sitemap.xml, and honoring robots.txt before fetching a URL. Both
+// features share the same fetch.Fetcher used by the scraper itself so
+// DynamicFetcher and StaticFetcher benefit from the same robots cache.
+package discovery
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jakopako/goskyr/fetch"
+)
+
+type sitemapIndex struct {
+	XMLName  xml.Name `xml:"sitemapindex"`
+	Sitemaps []struct {
+		Loc string `xml:"loc"`
+	} `xml:"sitemap"`
+}
+
+type urlSet struct {
+	XMLName xml.Name `xml:"urlset"`
+	URLs    []struct {
+		Loc     string `xml:"loc"`
+		LastMod string `xml:"lastmod"`
+	} `xml:"url"`
+}
+
+// SeedSitemap fetches <scheme>://<host>/sitemap.xml for baseURL, following
+// sitemap index entries recursively, and returns every <loc> URL found in
+// the leaf url sets. If since is greater than zero, entries whose
+// <lastmod> is older than since are skipped; entries without a <lastmod>
+// are always kept, since there's no way to tell how old they are. ctx
+// bounds every fetch along the way.
+func SeedSitemap(ctx context.Context, fetcher fetch.Fetcher, baseURL string, since time.Duration) ([]string, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("discovery: failed to parse base url %s: %w", baseURL, err)
+	}
+	sitemapURL := fmt.Sprintf("%s://%s/sitemap.xml", u.Scheme, u.Host)
+	return fetchSitemap(ctx, fetcher, sitemapURL, since, 0)
+}
+
+// maxSitemapDepth bounds the recursion through sitemap index entries so a
+// misconfigured or malicious site can't send us into an infinite loop.
+const maxSitemapDepth = 5
+
+func fetchSitemap(ctx context.Context, fetcher fetch.Fetcher, sitemapURL string, since time.Duration, depth int) ([]string, error) {
+	if depth > maxSitemapDepth {
+		return nil, fmt.Errorf("discovery: sitemap index nesting too deep at %s", sitemapURL)
+	}
+
+	res, err := fetchWithContext(ctx, fetcher, sitemapURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var idx sitemapIndex
+	if err := xml.Unmarshal([]byte(res), &idx); err == nil && len(idx.Sitemaps) > 0 {
+		var urls []string
+		for _, sm := range idx.Sitemaps {
+			childURLs, err := fetchSitemap(ctx, fetcher, sm.Loc, since, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			urls = append(urls, childURLs...)
+		}
+		return urls, nil
+	}
+
+	var set urlSet
+	if err := xml.Unmarshal([]byte(res), &set); err != nil {
+		return nil, fmt.Errorf("discovery: failed to parse sitemap %s: %w", sitemapURL, err)
+	}
+
+	cutoff := time.Time{}
+	if since > 0 {
+		cutoff = time.Now().Add(-since)
+	}
+
+	var urls []string
+	for _, u := range set.URLs {
+		if u.Loc == "" {
+			continue
+		}
+		if !cutoff.IsZero() && u.LastMod != "" {
+			lastMod, err := time.Parse(time.RFC3339, strings.TrimSpace(u.LastMod))
+			if err == nil && lastMod.Before(cutoff) {
+				continue
+			}
+		}
+		urls = append(urls, u.Loc)
+	}
+	return urls, nil
+}
+
+// ParseSince parses a duration string with an optional "d" (day) suffix
+// in addition to everything time.ParseDuration already understands, so
+// that config values like "7d" can be used for sitemap_since.
+func ParseSince(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	if strings.HasSuffix(s, "d") {
+		days := strings.TrimSuffix(s, "d")
+		d, err := time.ParseDuration(days + "h")
+		if err != nil {
+			return 0, fmt.Errorf("discovery: invalid sitemap_since %q: %w", s, err)
+		}
+		return d * 24, nil
+	}
+	return time.ParseDuration(s)
+}
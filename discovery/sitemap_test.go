@@ -0,0 +1,115 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fakeFetcher serves fixture files from testdata keyed by URL, so tests
+// can exercise SeedSitemap/Allowed without any network access.
+type fakeFetcher struct {
+	byURL map[string]string
+	calls map[string]int
+}
+
+func newFakeFetcher(byURL map[string]string) *fakeFetcher {
+	return &fakeFetcher{byURL: byURL, calls: map[string]int{}}
+}
+
+func (f *fakeFetcher) Fetch(url string) (string, error) {
+	f.calls[url]++
+	body, found := f.byURL[url]
+	if !found {
+		return "", fmt.Errorf("fakeFetcher: no fixture for %s", url)
+	}
+	return body, nil
+}
+
+func mustReadFixture(t *testing.T, name string) string {
+	t.Helper()
+	b, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatalf("failed to read fixture %s: %v", name, err)
+	}
+	return string(b)
+}
+
+func TestSeedSitemap_Basic(t *testing.T) {
+	fetcher := newFakeFetcher(map[string]string{
+		"https://example.com/sitemap.xml": mustReadFixture(t, "sitemap_basic.xml"),
+	})
+
+	urls, err := SeedSitemap(context.Background(), fetcher, "https://example.com/events", 0)
+	if err != nil {
+		t.Fatalf("SeedSitemap returned error: %v", err)
+	}
+
+	want := []string{"https://example.com/events/1", "https://example.com/events/2"}
+	if !reflect.DeepEqual(urls, want) {
+		t.Errorf("SeedSitemap() = %v, want %v", urls, want)
+	}
+}
+
+func TestSeedSitemap_FollowsIndex(t *testing.T) {
+	fetcher := newFakeFetcher(map[string]string{
+		"https://example.com/sitemap.xml":   mustReadFixture(t, "sitemap_index.xml"),
+		"https://example.com/sitemap-a.xml": mustReadFixture(t, "sitemap_a.xml"),
+		"https://example.com/sitemap-b.xml": mustReadFixture(t, "sitemap_b.xml"),
+	})
+
+	urls, err := SeedSitemap(context.Background(), fetcher, "https://example.com", 0)
+	if err != nil {
+		t.Fatalf("SeedSitemap returned error: %v", err)
+	}
+
+	want := []string{"https://example.com/a/1", "https://example.com/b/1"}
+	if !reflect.DeepEqual(urls, want) {
+		t.Errorf("SeedSitemap() = %v, want %v", urls, want)
+	}
+}
+
+func TestSeedSitemap_SinceFiltersOldEntries(t *testing.T) {
+	fetcher := newFakeFetcher(map[string]string{
+		"https://example.com/sitemap.xml": mustReadFixture(t, "sitemap_basic.xml"),
+	})
+
+	// sitemap_basic.xml's only <lastmod> is well in the past relative to
+	// a one-hour `since` window, so it should be dropped, but the entry
+	// with no <lastmod> at all must always survive.
+	urls, err := SeedSitemap(context.Background(), fetcher, "https://example.com", time.Hour)
+	if err != nil {
+		t.Fatalf("SeedSitemap returned error: %v", err)
+	}
+
+	want := []string{"https://example.com/events/2"}
+	if !reflect.DeepEqual(urls, want) {
+		t.Errorf("SeedSitemap() = %v, want %v", urls, want)
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{in: "", want: 0},
+		{in: "7d", want: 7 * 24 * time.Hour},
+		{in: "3h", want: 3 * time.Hour},
+		{in: "not-a-duration", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseSince(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseSince(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if err == nil && got != tt.want {
+			t.Errorf("ParseSince(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
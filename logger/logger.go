@@ -0,0 +1,56 @@
+// Package logger provides the structured logger shared by goskyr's
+// scrapers and writers. It wraps log/slog so that log level and
+// destination can be configured once, either from the global YAML config
+// or from the `-logger` CLI flag, instead of every package calling the
+// standard log package directly.
+package logger
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// Config configures the package-wide structured logger. It is meant to be
+// embedded under the `global.logger` key of the scraper configuration.
+type Config struct {
+	Level string `yaml:"level,omitempty"` // debug, info, warn, error. Defaults to info.
+	File  string `yaml:"file,omitempty"`  // optional path to a log file. Defaults to stderr.
+}
+
+// New builds a *slog.Logger according to c. If c.File is set, log records
+// are appended there instead of being written to stderr.
+func New(c Config) (*slog.Logger, error) {
+	level, err := parseLevel(c.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	var w io.Writer = os.Stderr
+	if c.File != "" {
+		f, err := os.OpenFile(c.File, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("logger: failed to open log file %s: %w", c.File, err)
+		}
+		w = f
+	}
+
+	h := slog.NewTextHandler(w, &slog.HandlerOptions{Level: level})
+	return slog.New(h), nil
+}
+
+func parseLevel(level string) (slog.Level, error) {
+	switch level {
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "debug":
+		return slog.LevelDebug, nil
+	case "warn":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return slog.LevelInfo, fmt.Errorf("logger: unknown level %q", level)
+	}
+}
@@ -2,101 +2,174 @@ package output
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"time"
 )
 
+func init() {
+	Register("api", newAPIWriter)
+}
+
+// WriterError is returned by a Writer when one or more items could not be
+// written. It keeps track of how many items were dropped so that callers
+// can decide whether to retry or abort, instead of the process being torn
+// down on the first failed request.
+type WriterError struct {
+	Op         string
+	StatusCode int
+	Items      int
+	Err        error
+}
+
+func (e *WriterError) Error() string {
+	return fmt.Sprintf("%s failed (status %d, %d items): %v", e.Op, e.StatusCode, e.Items, e.Err)
+}
+
+func (e *WriterError) Unwrap() error {
+	return e.Err
+}
+
 // The APIWriter is meant to write to a custom API and assumes many things.
 // So currently, it is better not to use this APIWriter.
 type APIWriter struct {
 	writerConfig *WriterConfig
+	logger       *slog.Logger
 }
 
 // NewAPIWriter returns a new APIWriter
-func NewAPIWriter(wc *WriterConfig) *APIWriter {
+func NewAPIWriter(wc *WriterConfig, logger *slog.Logger) *APIWriter {
 	return &APIWriter{
 		writerConfig: wc,
+		logger:       logger,
 	}
 }
 
-func (f *APIWriter) Write(items chan map[string]interface{}) {
+func newAPIWriter(wc *WriterConfig, logger *slog.Logger) (Writer, error) {
+	return NewAPIWriter(wc, logger), nil
+}
+
+func (f *APIWriter) Write(ctx context.Context, items <-chan map[string]interface{}) error {
 	client := &http.Client{
 		Timeout: time.Second * 10,
 	}
 	apiURL := f.writerConfig.Uri
 	apiUser := f.writerConfig.User
 	apiPassword := f.writerConfig.Password
+	batchSize := f.writerConfig.BatchSize
+	if batchSize <= 0 {
+		batchSize = 100
+	}
 
 	deletedSources := map[string]bool{}
 	nrItems := 0
+	nrDropped := 0
 	batch := []map[string]interface{}{}
 
+	flush := func(b []map[string]interface{}) {
+		if len(b) == 0 {
+			return
+		}
+		if err := postBatch(client, b, apiURL, apiUser, apiPassword); err != nil {
+			f.logger.Error("failed to post batch to api", "error", err, "items", len(b))
+			nrDropped += len(b)
+		}
+	}
+
 	// This code assumes that within one source, items are ordered
 	// by date ascending.
-	for item := range items {
-		nrItems++
-		currentSrc := item["sourceUrl"].(string)
-		if _, found := deletedSources[currentSrc]; !found {
-			deletedSources[currentSrc] = true
-			// delete all items from the given source
-			firstDate, ok := item["date"].(time.Time)
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			break loop
+		case item, ok := <-items:
 			if !ok {
-				log.Fatalf("error while trying to cast the date field of item %v to time.Time", item)
+				break loop
 			}
-			firstDateUTCF := firstDate.UTC().Format("2006-01-02 15:04")
-			deleteURL := fmt.Sprintf("%s?sourceUrl=%s&datetime=%s", apiURL, url.QueryEscape(currentSrc), url.QueryEscape(firstDateUTCF))
-			req, _ := http.NewRequest("DELETE", deleteURL, nil)
-			req.SetBasicAuth(apiUser, apiPassword)
-			resp, err := client.Do(req)
-			if err != nil {
-				log.Fatal(err)
+			nrItems++
+			currentSrc, ok := item["sourceUrl"].(string)
+			if !ok {
+				f.logger.Error("item is missing a sourceUrl, dropping item", "item", item)
+				nrDropped++
+				continue
 			}
-			if resp.StatusCode != 200 {
-				body, err := io.ReadAll(resp.Body)
-				if err != nil {
-					log.Fatal(err)
+			if _, found := deletedSources[currentSrc]; !found {
+				deletedSources[currentSrc] = true
+				// delete all items from the given source
+				firstDate, ok := item["date"].(time.Time)
+				if !ok {
+					f.logger.Error("could not cast date field to time.Time, skipping delete for source", "source", currentSrc)
+				} else if err := deleteSource(client, apiURL, apiUser, apiPassword, currentSrc, firstDate); err != nil {
+					f.logger.Error("failed to delete existing items for source", "error", err, "source", currentSrc)
 				}
-				log.Fatalf("something went wrong while deleting items. Status Code: %d\nUrl: %s Response: %s", resp.StatusCode, deleteURL, body)
 			}
-			resp.Body.Close()
-		}
-		batch = append(batch, item)
-		if len(batch) == 100 {
-			postBatch(client, batch, apiURL, apiUser, apiPassword)
-			batch = []map[string]interface{}{}
+			batch = append(batch, item)
+			if len(batch) == batchSize {
+				flush(batch)
+				batch = []map[string]interface{}{}
+			}
 		}
 	}
-	postBatch(client, batch, apiURL, apiUser, apiPassword)
+	flush(batch)
+
+	f.logger.Info("finished writing to api", "wrote", nrItems-nrDropped, "dropped", nrDropped, "sources", len(deletedSources))
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if nrDropped > 0 {
+		return &WriterError{Op: "api write", Items: nrDropped, Err: errors.New("some items could not be written")}
+	}
+	return nil
+}
 
-	log.Printf("wrote %d items from %d sources to the api", nrItems, len(deletedSources))
+func deleteSource(client *http.Client, apiURL, apiUser, apiPassword, sourceURL string, firstDate time.Time) error {
+	firstDateUTCF := firstDate.UTC().Format("2006-01-02 15:04")
+	deleteURL := fmt.Sprintf("%s?sourceUrl=%s&datetime=%s", apiURL, url.QueryEscape(sourceURL), url.QueryEscape(firstDateUTCF))
+	req, err := http.NewRequest("DELETE", deleteURL, nil)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(apiUser, apiPassword)
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		body, _ := io.ReadAll(resp.Body)
+		return &WriterError{Op: "delete", StatusCode: resp.StatusCode, Items: 1, Err: fmt.Errorf("url %s: %s", deleteURL, body)}
+	}
+	return nil
 }
 
-func postBatch(client *http.Client, batch []map[string]interface{}, apiURL, apiUser, apiPassword string) {
+func postBatch(client *http.Client, batch []map[string]interface{}, apiURL, apiUser, apiPassword string) error {
 	concertJSON, err := json.Marshal(batch)
 	if err != nil {
-		log.Fatal(err)
+		return err
+	}
+	req, err := http.NewRequest("POST", apiURL, bytes.NewBuffer(concertJSON))
+	if err != nil {
+		return err
 	}
-	req, _ := http.NewRequest("POST", apiURL, bytes.NewBuffer(concertJSON))
 	req.Header = map[string][]string{
 		"Content-Type": {"application/json"},
 	}
 	req.SetBasicAuth(apiUser, apiPassword)
 	resp, err := client.Do(req)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
+	defer resp.Body.Close()
 	if resp.StatusCode != 201 {
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Fatalf("something went wrong while adding new events. Status Code: %d Response: %s", resp.StatusCode, body)
+		body, _ := io.ReadAll(resp.Body)
+		return &WriterError{Op: "post", StatusCode: resp.StatusCode, Items: len(batch), Err: fmt.Errorf("%s", body)}
 	}
-	resp.Body.Close()
-
+	return nil
 }
@@ -0,0 +1,27 @@
+package output
+
+// WriterConfig holds the configuration for the writer items are sent to.
+// Which fields apply depends on Type; each built-in writer documents the
+// ones it reads.
+type WriterConfig struct {
+	Type string `yaml:"type,omitempty"` // api (default), stdout, csv, sqlite, webhook
+
+	// api
+	Uri      string `yaml:"uri,omitempty"`
+	User     string `yaml:"user,omitempty"`
+	Password string `yaml:"password,omitempty"`
+
+	// csv
+	File    string   `yaml:"file,omitempty"`
+	Columns []string `yaml:"columns,omitempty"` // column order; scraper.NewConfig fills it from Field names if empty; derived from the items if still empty
+
+	// sqlite
+	DB    string `yaml:"db,omitempty"`
+	Table string `yaml:"table,omitempty"` // defaults to "items"
+
+	// webhook
+	Url    string `yaml:"url,omitempty"`
+	Secret string `yaml:"secret,omitempty"` // if set, signs each request with HMAC-SHA256
+
+	BatchSize int `yaml:"batch_size,omitempty"` // api, webhook. Defaults to 100 for api, 1 for webhook.
+}
@@ -0,0 +1,145 @@
+package output
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+	"sort"
+	"time"
+)
+
+func init() {
+	Register("csv", newCSVWriter)
+}
+
+// CSVWriter streams items to a CSV file. The column order is taken from
+// writerConfig.Columns, which scraper.NewConfig populates with the union
+// of Field.Names configured across all scrapers unless set explicitly. If
+// Columns is still empty, every item is buffered so the header can cover
+// the union of keys across all of them instead of just the first item.
+type CSVWriter struct {
+	writerConfig *WriterConfig
+	logger       *slog.Logger
+}
+
+func newCSVWriter(wc *WriterConfig, logger *slog.Logger) (Writer, error) {
+	return &CSVWriter{writerConfig: wc, logger: logger}, nil
+}
+
+func (w *CSVWriter) Write(ctx context.Context, items <-chan map[string]interface{}) error {
+	f, err := os.Create(w.writerConfig.File)
+	if err != nil {
+		return fmt.Errorf("csv writer: %w", err)
+	}
+	defer f.Close()
+
+	cw := csv.NewWriter(f)
+	defer cw.Flush()
+
+	columns := w.writerConfig.Columns
+	if len(columns) == 0 {
+		return w.writeWithDerivedColumns(ctx, cw, items)
+	}
+	if err := cw.Write(columns); err != nil {
+		return fmt.Errorf("csv writer: %w", err)
+	}
+
+	nrItems := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case item, ok := <-items:
+			if !ok {
+				cw.Flush()
+				w.logger.Info("finished writing to csv", "wrote", nrItems, "file", w.writerConfig.File)
+				return cw.Error()
+			}
+			row := make([]string, len(columns))
+			for i, c := range columns {
+				row[i] = formatCSVValue(item[c])
+			}
+			if err := cw.Write(row); err != nil {
+				return fmt.Errorf("csv writer: %w", err)
+			}
+			nrItems++
+		}
+	}
+}
+
+// writeWithDerivedColumns handles the case where no column order was
+// configured. It buffers every item so the header can be the union of
+// keys across all of them, rather than locking in whichever item happens
+// to arrive first and silently dropping any key a later item adds.
+func (w *CSVWriter) writeWithDerivedColumns(ctx context.Context, cw *csv.Writer, items <-chan map[string]interface{}) error {
+	var buffered []map[string]interface{}
+	seen := map[string]bool{}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case item, ok := <-items:
+			if !ok {
+				columns := sortedKeys(seen)
+				if err := cw.Write(columns); err != nil {
+					return fmt.Errorf("csv writer: %w", err)
+				}
+				for _, it := range buffered {
+					row := make([]string, len(columns))
+					for i, c := range columns {
+						row[i] = formatCSVValue(it[c])
+					}
+					if err := cw.Write(row); err != nil {
+						return fmt.Errorf("csv writer: %w", err)
+					}
+				}
+				cw.Flush()
+				w.logger.Info("finished writing to csv", "wrote", len(buffered), "file", w.writerConfig.File)
+				return cw.Error()
+			}
+			for k := range item {
+				seen[k] = true
+			}
+			buffered = append(buffered, item)
+		}
+	}
+}
+
+// formatCSVValue renders a single item value as a CSV cell. output can't
+// import scraper (scraper already imports output), so rather than type
+// switching on scraper.MatchHighlight directly, any map/slice/struct value
+// - e.g. the match highlights scraper stores under "_matches" - is
+// JSON-encoded instead of falling through to fmt.Sprint's Go-syntax dump,
+// which a downstream CSV consumer couldn't parse.
+func formatCSVValue(v interface{}) string {
+	switch tv := v.(type) {
+	case time.Time:
+		return tv.Format(time.RFC3339)
+	case nil:
+		return ""
+	case string:
+		return tv
+	}
+
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct, reflect.Ptr:
+		b, err := json.Marshal(v)
+		if err == nil {
+			return string(b)
+		}
+	}
+	return fmt.Sprint(v)
+}
+
+func sortedKeys(keySet map[string]bool) []string {
+	keys := make([]string, 0, len(keySet))
+	for k := range keySet {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
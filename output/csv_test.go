@@ -0,0 +1,89 @@
+package output
+
+import (
+	"context"
+	"encoding/csv"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFormatCSVValue(t *testing.T) {
+	tests := []struct {
+		name string
+		in   interface{}
+		want string
+	}{
+		{name: "nil", in: nil, want: ""},
+		{name: "string", in: "jazz night", want: "jazz night"},
+		{name: "int", in: 42, want: "42"},
+		{name: "time", in: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), want: "2024-01-02T03:04:05Z"},
+		{
+			name: "match highlights map",
+			in: map[string][]struct {
+				Value   string `json:"value"`
+				Partial bool   `json:"partial"`
+			}{
+				"title": {{Value: "jazz", Partial: true}},
+			},
+			want: `{"title":[{"value":"jazz","partial":true}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatCSVValue(tt.in); got != tt.want {
+				t.Errorf("formatCSVValue(%#v) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCSVWriter_WriteWithDerivedColumns(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "out.csv")
+	w := &CSVWriter{
+		writerConfig: &WriterConfig{File: file},
+		logger:       slog.New(slog.NewTextHandler(os.Stderr, nil)),
+	}
+
+	items := make(chan map[string]interface{}, 2)
+	items <- map[string]interface{}{"title": "a", "location": "venue"}
+	items <- map[string]interface{}{"title": "b"}
+	close(items)
+
+	if err := w.Write(context.Background(), items); err != nil {
+		t.Fatalf("Write() returned error: %v", err)
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		t.Fatalf("failed to open written csv: %v", err)
+	}
+	defer f.Close()
+
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to read written csv: %v", err)
+	}
+
+	wantHeader := []string{"location", "title"}
+	if len(rows) == 0 || !equalStrings(rows[0], wantHeader) {
+		t.Fatalf("header = %v, want %v (union of keys across all items, sorted)", rows[0], wantHeader)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("got %d rows, want 3 (header + 2 items)", len(rows))
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
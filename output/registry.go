@@ -0,0 +1,38 @@
+package output
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// Writer is implemented by every output sink goskyr can write scraped
+// items to. Write should drain items until the channel is closed or ctx
+// is cancelled, whichever happens first.
+type Writer interface {
+	Write(ctx context.Context, items <-chan map[string]interface{}) error
+}
+
+type factory func(*WriterConfig, *slog.Logger) (Writer, error)
+
+var registry = map[string]factory{}
+
+// Register makes a writer implementation available under name. Built-in
+// writers call this from their own init() so that third parties can add
+// new sinks without touching this file.
+func Register(name string, f factory) {
+	registry[name] = f
+}
+
+// New builds the Writer configured by wc.
+func New(wc *WriterConfig, log *slog.Logger) (Writer, error) {
+	typ := wc.Type
+	if typ == "" {
+		typ = "api"
+	}
+	f, ok := registry[typ]
+	if !ok {
+		return nil, fmt.Errorf("output: unknown writer type %q", typ)
+	}
+	return f(wc, log)
+}
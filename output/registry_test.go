@@ -0,0 +1,58 @@
+package output
+
+import (
+	"log/slog"
+	"os"
+	"testing"
+)
+
+func TestNew_DispatchesByType(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(os.Stderr, nil))
+
+	tests := []struct {
+		typ  string
+		want interface{}
+	}{
+		{typ: "", want: &APIWriter{}},
+		{typ: "api", want: &APIWriter{}},
+		{typ: "stdout", want: &StdoutWriter{}},
+		{typ: "csv", want: &CSVWriter{}},
+		{typ: "sqlite", want: &SQLiteWriter{}},
+		{typ: "webhook", want: &WebhookWriter{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.typ, func(t *testing.T) {
+			w, err := New(&WriterConfig{Type: tt.typ}, log)
+			if err != nil {
+				t.Fatalf("New(type=%q) returned error: %v", tt.typ, err)
+			}
+			if got, want := typeName(w), typeName(tt.want); got != want {
+				t.Errorf("New(type=%q) = %s, want %s", tt.typ, got, want)
+			}
+		})
+	}
+}
+
+func TestNew_UnknownTypeErrors(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	if _, err := New(&WriterConfig{Type: "carrier-pigeon"}, log); err == nil {
+		t.Error("New() with an unregistered type = nil error, want non-nil")
+	}
+}
+
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case *APIWriter:
+		return "api"
+	case *StdoutWriter:
+		return "stdout"
+	case *CSVWriter:
+		return "csv"
+	case *SQLiteWriter:
+		return "sqlite"
+	case *WebhookWriter:
+		return "webhook"
+	default:
+		return "unknown"
+	}
+}
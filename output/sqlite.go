@@ -0,0 +1,84 @@
+package output
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+func init() {
+	Register("sqlite", newSQLiteWriter)
+}
+
+// SQLiteWriter upserts items into a local sqlite database, keyed by
+// (sourceUrl, date, title). Unlike APIWriter's delete-then-post dance this
+// allows delta updates: items that didn't change since the last run are
+// simply left untouched. modernc.org/sqlite is used instead of a cgo
+// sqlite driver so goskyr keeps building without a C toolchain.
+type SQLiteWriter struct {
+	writerConfig *WriterConfig
+	logger       *slog.Logger
+}
+
+func newSQLiteWriter(wc *WriterConfig, logger *slog.Logger) (Writer, error) {
+	if wc.Table == "" {
+		wc.Table = "items"
+	}
+	return &SQLiteWriter{writerConfig: wc, logger: logger}, nil
+}
+
+func (w *SQLiteWriter) Write(ctx context.Context, items <-chan map[string]interface{}) error {
+	db, err := sql.Open("sqlite", w.writerConfig.DB)
+	if err != nil {
+		return fmt.Errorf("sqlite writer: %w", err)
+	}
+	defer db.Close()
+
+	createStmt := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		sourceUrl TEXT NOT NULL,
+		date TEXT NOT NULL,
+		title TEXT NOT NULL,
+		data TEXT NOT NULL,
+		PRIMARY KEY (sourceUrl, date, title)
+	)`, w.writerConfig.Table)
+	if _, err := db.ExecContext(ctx, createStmt); err != nil {
+		return fmt.Errorf("sqlite writer: %w", err)
+	}
+
+	upsertStmt := fmt.Sprintf(`INSERT INTO %s (sourceUrl, date, title, data) VALUES (?, ?, ?, ?)
+		ON CONFLICT(sourceUrl, date, title) DO UPDATE SET data=excluded.data`, w.writerConfig.Table)
+
+	nrItems := 0
+	nrDropped := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case item, ok := <-items:
+			if !ok {
+				w.logger.Info("finished writing to sqlite", "wrote", nrItems, "dropped", nrDropped, "db", w.writerConfig.DB)
+				return nil
+			}
+			sourceURL, _ := item["sourceUrl"].(string)
+			title, _ := item["title"].(string)
+			date, _ := item["date"].(time.Time)
+			data, err := json.Marshal(item)
+			if err != nil {
+				w.logger.Error("failed to marshal item, skipping", "error", err, "item", item)
+				nrDropped++
+				continue
+			}
+			if _, err := db.ExecContext(ctx, upsertStmt, sourceURL, date.UTC().Format(time.RFC3339), title, string(data)); err != nil {
+				w.logger.Error("failed to upsert item, skipping", "error", err, "item", item)
+				nrDropped++
+				continue
+			}
+			nrItems++
+		}
+	}
+}
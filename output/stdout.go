@@ -2,39 +2,67 @@ package output
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"log"
-	"sync"
+	"log/slog"
 )
 
-type StdoutWriter struct{}
+func init() {
+	Register("stdout", newStdoutWriter)
+}
 
-func (s *StdoutWriter) Write(items chan map[string]interface{}, wg *sync.WaitGroup) {
-	defer wg.Done()
-	for item := range items {
-		// We cannot use the following line of code because it automatically replaces certain html characters
-		// with the corresponding Unicode replacement rune.
-		// itemsJson, err := json.MarshalIndent(items, "", "  ")
-		// if err != nil {
-		// 	log.Print(err.Error())
-		// }
-		// See
-		// https://stackoverflow.com/questions/28595664/how-to-stop-json-marshal-from-escaping-and
-		// https://developpaper.com/the-solution-of-escaping-special-html-characters-in-golang-json-marshal/
-		buffer := &bytes.Buffer{}
-		encoder := json.NewEncoder(buffer)
-		encoder.SetEscapeHTML(false)
-		if err := encoder.Encode(item); err != nil {
-			log.Printf("StdoutWriter ERROR while writing item %v: %v", item, err)
-			continue
-		}
+type StdoutWriter struct {
+	logger *slog.Logger
+}
+
+// NewStdoutWriter returns a new StdoutWriter
+func NewStdoutWriter(logger *slog.Logger) *StdoutWriter {
+	return &StdoutWriter{
+		logger: logger,
+	}
+}
+
+func newStdoutWriter(wc *WriterConfig, logger *slog.Logger) (Writer, error) {
+	return NewStdoutWriter(logger), nil
+}
+
+func (s *StdoutWriter) Write(ctx context.Context, items <-chan map[string]interface{}) error {
+	nrItems := 0
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("finished writing to stdout", "wrote", nrItems)
+			return ctx.Err()
+		case item, ok := <-items:
+			if !ok {
+				s.logger.Info("finished writing to stdout", "wrote", nrItems)
+				return nil
+			}
+			// We cannot use the following line of code because it automatically replaces certain html characters
+			// with the corresponding Unicode replacement rune.
+			// itemsJson, err := json.MarshalIndent(items, "", "  ")
+			// if err != nil {
+			// 	log.Print(err.Error())
+			// }
+			// See
+			// https://stackoverflow.com/questions/28595664/how-to-stop-json-marshal-from-escaping-and
+			// https://developpaper.com/the-solution-of-escaping-special-html-characters-in-golang-json-marshal/
+			buffer := &bytes.Buffer{}
+			encoder := json.NewEncoder(buffer)
+			encoder.SetEscapeHTML(false)
+			if err := encoder.Encode(item); err != nil {
+				s.logger.Error("failed to encode item, skipping", "error", err, "item", item)
+				continue
+			}
 
-		var indentBuffer bytes.Buffer
-		if err := json.Indent(&indentBuffer, buffer.Bytes(), "", "  "); err != nil {
-			log.Printf("StdoutWriter ERROR while writing item %v: %v", item, err)
-			continue
+			var indentBuffer bytes.Buffer
+			if err := json.Indent(&indentBuffer, buffer.Bytes(), "", "  "); err != nil {
+				s.logger.Error("failed to indent item, skipping", "error", err, "item", item)
+				continue
+			}
+			fmt.Print(indentBuffer.String())
+			nrItems++
 		}
-		fmt.Print(indentBuffer.String())
 	}
 }
@@ -0,0 +1,106 @@
+package output
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+func init() {
+	Register("webhook", newWebhookWriter)
+}
+
+// signatureHeader carries the HMAC-SHA256 signature of the request body,
+// hex-encoded, so receivers can verify a batch actually came from us.
+const signatureHeader = "X-Goskyr-Signature"
+
+// WebhookWriter POSTs items, batched up to writerConfig.BatchSize at a
+// time, to writerConfig.Url. If writerConfig.Secret is set, each request
+// is signed with an HMAC-SHA256 of the body.
+type WebhookWriter struct {
+	writerConfig *WriterConfig
+	logger       *slog.Logger
+}
+
+func newWebhookWriter(wc *WriterConfig, logger *slog.Logger) (Writer, error) {
+	if wc.BatchSize <= 0 {
+		wc.BatchSize = 1
+	}
+	return &WebhookWriter{writerConfig: wc, logger: logger}, nil
+}
+
+func (w *WebhookWriter) Write(ctx context.Context, items <-chan map[string]interface{}) error {
+	client := &http.Client{Timeout: time.Second * 10}
+	batch := make([]map[string]interface{}, 0, w.writerConfig.BatchSize)
+	nrItems := 0
+	nrDropped := 0
+
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := w.post(ctx, client, batch); err != nil {
+			w.logger.Error("failed to post batch to webhook", "error", err, "items", len(batch))
+			nrDropped += len(batch)
+		} else {
+			nrItems += len(batch)
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case item, ok := <-items:
+			if !ok {
+				flush()
+				w.logger.Info("finished writing to webhook", "wrote", nrItems, "dropped", nrDropped)
+				if nrDropped > 0 {
+					return &WriterError{Op: "webhook write", Items: nrDropped, Err: errors.New("some items could not be delivered")}
+				}
+				return nil
+			}
+			batch = append(batch, item)
+			if len(batch) == w.writerConfig.BatchSize {
+				flush()
+			}
+		}
+	}
+}
+
+func (w *WebhookWriter) post(ctx context.Context, client *http.Client, batch []map[string]interface{}) error {
+	body, err := json.Marshal(batch)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", w.writerConfig.Url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if w.writerConfig.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(w.writerConfig.Secret))
+		mac.Write(body)
+		req.Header.Set(signatureHeader, hex.EncodeToString(mac.Sum(nil)))
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return &WriterError{Op: "webhook post", StatusCode: resp.StatusCode, Items: len(batch), Err: fmt.Errorf("%s", respBody)}
+	}
+	return nil
+}
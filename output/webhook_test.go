@@ -0,0 +1,84 @@
+package output
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestWebhookWriter_SignsBatchWithHMAC(t *testing.T) {
+	const secret = "s3cr3t"
+
+	var gotBody []byte
+	var gotSignature string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotBody, _ = io.ReadAll(r.Body)
+		gotSignature = r.Header.Get(signatureHeader)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	wc := &WriterConfig{Url: server.URL, Secret: secret, BatchSize: 2}
+	writer, err := newWebhookWriter(wc, slog.New(slog.NewTextHandler(os.Stderr, nil)))
+	if err != nil {
+		t.Fatalf("newWebhookWriter() returned error: %v", err)
+	}
+
+	items := make(chan map[string]interface{}, 2)
+	items <- map[string]interface{}{"title": "a"}
+	items <- map[string]interface{}{"title": "b"}
+	close(items)
+
+	if err := writer.Write(context.Background(), items); err != nil {
+		t.Fatalf("Write() returned error: %v", err)
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(gotBody)
+	want := hex.EncodeToString(mac.Sum(nil))
+	if gotSignature != want {
+		t.Errorf("signature header = %q, want %q", gotSignature, want)
+	}
+
+	var batch []map[string]interface{}
+	if err := json.Unmarshal(gotBody, &batch); err != nil {
+		t.Fatalf("posted body is not valid json: %v", err)
+	}
+	if len(batch) != 2 {
+		t.Errorf("got %d items in posted batch, want 2", len(batch))
+	}
+}
+
+func TestWebhookWriter_NoSecretOmitsSignature(t *testing.T) {
+	sawHeader := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, sawHeader = r.Header[signatureHeader]
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	wc := &WriterConfig{Url: server.URL, BatchSize: 1}
+	writer, err := newWebhookWriter(wc, slog.New(slog.NewTextHandler(os.Stderr, nil)))
+	if err != nil {
+		t.Fatalf("newWebhookWriter() returned error: %v", err)
+	}
+
+	items := make(chan map[string]interface{}, 1)
+	items <- map[string]interface{}{"title": "a"}
+	close(items)
+
+	if err := writer.Write(context.Background(), items); err != nil {
+		t.Fatalf("Write() returned error: %v", err)
+	}
+	if sawHeader {
+		t.Errorf("signature header present without a configured secret")
+	}
+}
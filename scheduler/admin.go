@@ -0,0 +1,70 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/jakopako/goskyr/scraper"
+)
+
+// targetStatus is the JSON shape served by the /targets admin endpoint.
+type targetStatus struct {
+	Name              string    `json:"name"`
+	LastSuccess       time.Time `json:"lastSuccess,omitempty"`
+	LastError         string    `json:"lastError,omitempty"`
+	ConsecutiveErrors int       `json:"consecutiveErrors"`
+	ItemsEmitted      int       `json:"itemsEmitted"`
+}
+
+// TargetsHandler serves the current health of every scheduled target as
+// JSON, for use as the pool's "http admin" /targets endpoint.
+func (p *TargetPool) TargetsHandler(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	statuses := make([]targetStatus, 0, len(p.targets))
+	for _, t := range p.targets {
+		t.mu.Lock()
+		ts := targetStatus{
+			Name:              t.scraper.Name,
+			LastSuccess:       t.health.LastSuccess,
+			ConsecutiveErrors: t.health.ConsecutiveErrors,
+			ItemsEmitted:      t.health.ItemsEmitted,
+		}
+		if t.health.LastError != nil {
+			ts.LastError = t.health.LastError.Error()
+		}
+		t.mu.Unlock()
+		statuses = append(statuses, ts)
+	}
+	p.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(statuses); err != nil {
+		p.log.Error("failed to encode target statuses", "error", err)
+	}
+}
+
+// ReloadHandler re-reads the config at configPath and syncs the pool
+// against it, adding and removing targets without restarting the ones
+// that didn't change. It mirrors Prometheus' "/-/reload" endpoint.
+func (p *TargetPool) ReloadHandler(configPath string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		cfg, err := scraper.NewConfig(configPath)
+		if err != nil {
+			p.log.Error("failed to reload config", "error", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		p.global.Store(&cfg.Global)
+		if err := p.Sync(cfg.Scrapers); err != nil {
+			p.log.Error("failed to sync targets after reload", "error", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+}
@@ -0,0 +1,246 @@
+// Package scheduler runs a set of scraper.Scraper targets on their own
+// intervals, borrowing the design of Prometheus' target pool: every
+// target gets its own goroutine, ticks are staggered by a deterministic
+// jitter so targets sharing an interval don't all fire at once, and each
+// target's health is tracked independently so one misbehaving source
+// doesn't affect the others.
+package scheduler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jakopako/goskyr/scraper"
+	"gopkg.in/yaml.v2"
+)
+
+// Health describes the runtime state of a single scheduled target.
+type Health struct {
+	LastSuccess       time.Time
+	LastError         error
+	ConsecutiveErrors int
+	ItemsEmitted      int
+}
+
+// target couples a scraper.Scraper with its schedule and runtime state.
+type target struct {
+	scraper     scraper.Scraper
+	contentHash string
+
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu     sync.Mutex
+	health Health
+}
+
+// TargetPool owns the set of actively-scheduled scrapers and multiplexes
+// the items they emit into a single channel feeding the configured Writer.
+type TargetPool struct {
+	global atomic.Pointer[scraper.GlobalConfig]
+	log    *slog.Logger
+	items  chan map[string]interface{}
+
+	mu      sync.Mutex
+	targets map[string]*target
+}
+
+// NewTargetPool creates an empty TargetPool. Items scraped by any target
+// are sent on the channel returned by Items, which the caller is expected to feed
+// into an output.Writer. The channel is never closed by the pool itself;
+// callers relying on "run once and exit" semantics should use RunOnce
+// instead.
+func NewTargetPool(global *scraper.GlobalConfig, log *slog.Logger) *TargetPool {
+	p := &TargetPool{
+		log:     log,
+		items:   make(chan map[string]interface{}),
+		targets: map[string]*target{},
+	}
+	p.global.Store(global)
+	return p
+}
+
+// Items returns the channel items scraped by any target are sent on.
+func (p *TargetPool) Items() <-chan map[string]interface{} {
+	return p.items
+}
+
+// Sync starts, stops and restarts goroutines so that the running targets
+// match scrapers. Targets are compared by name and by a content hash of
+// their YAML representation: a scraper whose configuration didn't change
+// keeps running undisturbed, new scrapers are started, removed scrapers
+// are stopped and changed scrapers are restarted. This is what backs the
+// "/-/reload" admin endpoint, but it's also used to start the pool for
+// the first time.
+func (p *TargetPool) Sync(scrapers []scraper.Scraper) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	seen := map[string]bool{}
+	for _, s := range scrapers {
+		hash, err := contentHash(s)
+		if err != nil {
+			return fmt.Errorf("scheduler: failed to hash scraper %s: %w", s.Name, err)
+		}
+		seen[s.Name] = true
+
+		existing, found := p.targets[s.Name]
+		if found && existing.contentHash == hash {
+			continue // unchanged, leave the running goroutine alone
+		}
+		if found {
+			p.stopTarget(existing)
+		}
+		p.startTarget(s, hash)
+	}
+
+	for name, existing := range p.targets {
+		if !seen[name] {
+			p.stopTarget(existing)
+			delete(p.targets, name)
+		}
+	}
+	return nil
+}
+
+func (p *TargetPool) startTarget(s scraper.Scraper, hash string) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t := &target{
+		scraper:     s,
+		contentHash: hash,
+		cancel:      cancel,
+		done:        make(chan struct{}),
+	}
+	p.targets[s.Name] = t
+	go p.run(ctx, t)
+}
+
+// stopTarget cancels t's context and waits for its goroutine to exit.
+// This blocks while holding p.mu (see Sync), which is only safe because
+// scraper.GetItems races every fetch against ctx via fetchWithContext, so
+// cancellation unblocks a running scrape promptly instead of waiting for
+// a stuck fetch to return on its own.
+func (p *TargetPool) stopTarget(t *target) {
+	t.cancel()
+	<-t.done
+}
+
+// run is the per-target goroutine: it waits out a deterministic jitter
+// delay and then fires on a regular ticker until ctx is cancelled.
+func (p *TargetPool) run(ctx context.Context, t *target) {
+	defer close(t.done)
+
+	interval := t.scraper.ScrapeInterval
+	if interval <= 0 {
+		p.scrape(ctx, t)
+		return
+	}
+
+	timer := time.NewTimer(jitter(t.scraper.Name, interval))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return
+	case <-timer.C:
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		p.scrape(ctx, t)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *TargetPool) scrape(ctx context.Context, t *target) {
+	timeout := t.scraper.ScrapeTimeout
+	if timeout <= 0 {
+		timeout = t.scraper.ScrapeInterval
+	}
+	scrapeCtx := ctx
+	var cancel context.CancelFunc
+	if timeout > 0 {
+		scrapeCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	items, err := t.scraper.GetItems(scrapeCtx, p.global.Load(), p.log)
+
+	t.mu.Lock()
+	if err != nil {
+		t.health.LastError = err
+		t.health.ConsecutiveErrors++
+	} else {
+		t.health.LastSuccess = time.Now()
+		t.health.ConsecutiveErrors = 0
+		t.health.ItemsEmitted += len(items)
+	}
+	t.mu.Unlock()
+
+	if err != nil {
+		p.log.Error("scrape failed", "source", t.scraper.Name, "error", err)
+	}
+	for _, item := range items {
+		select {
+		case p.items <- item:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// jitter deterministically spreads out targets that share the same
+// interval by offsetting their first tick by a hash of the scraper name
+// modulo the interval, so restarting the pool doesn't realign everything
+// into a thundering herd.
+func jitter(name string, interval time.Duration) time.Duration {
+	if interval <= 0 {
+		return 0
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name))
+	return time.Duration(int64(h.Sum32()) % int64(interval))
+}
+
+// contentHash returns a stable hash of a scraper's YAML representation,
+// used to decide whether a target actually changed across a reload.
+func contentHash(s scraper.Scraper) (string, error) {
+	b, err := yaml.Marshal(s)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// RunOnce scrapes every configured scraper sequentially and returns the
+// combined items, preserving the pre-scheduler "run once and exit"
+// behaviour for callers that don't want a long-running pool.
+func RunOnce(ctx context.Context, cfg *scraper.Config, log *slog.Logger) ([]map[string]interface{}, error) {
+	var items []map[string]interface{}
+	var errs []error
+	for _, s := range cfg.Scrapers {
+		scraped, err := s.GetItems(ctx, &cfg.Global, log)
+		if err != nil {
+			log.Error("scrape failed", "source", s.Name, "error", err)
+			errs = append(errs, err)
+			continue
+		}
+		items = append(items, scraped...)
+	}
+	if len(errs) > 0 {
+		return items, fmt.Errorf("scheduler: %d of %d scrapers failed", len(errs), len(cfg.Scrapers))
+	}
+	return items, nil
+}
@@ -0,0 +1,211 @@
+package scheduler
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/jakopako/goskyr/scraper"
+)
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// longInterval is long enough that no target's ticker ever fires during a
+// test, so Sync's bookkeeping can be exercised without triggering a real
+// scrape (and therefore without any network access).
+const longInterval = time.Hour
+
+// stopAll stops every target still running in p, so a test doesn't leak
+// goroutines into the next one.
+func stopAll(p *TargetPool) {
+	_ = p.Sync(nil)
+}
+
+func TestSync_AddsNewTargets(t *testing.T) {
+	p := NewTargetPool(&scraper.GlobalConfig{}, testLogger())
+	defer stopAll(p)
+
+	err := p.Sync([]scraper.Scraper{
+		{Name: "a", URL: "https://a.example", ScrapeInterval: longInterval},
+		{Name: "b", URL: "https://b.example", ScrapeInterval: longInterval},
+	})
+	if err != nil {
+		t.Fatalf("Sync() returned error: %v", err)
+	}
+
+	if len(p.targets) != 2 {
+		t.Fatalf("got %d targets, want 2", len(p.targets))
+	}
+	for _, name := range []string{"a", "b"} {
+		if _, ok := p.targets[name]; !ok {
+			t.Errorf("target %q missing after Sync", name)
+		}
+	}
+}
+
+func TestSync_RemovesDroppedTargets(t *testing.T) {
+	p := NewTargetPool(&scraper.GlobalConfig{}, testLogger())
+	defer stopAll(p)
+
+	if err := p.Sync([]scraper.Scraper{
+		{Name: "a", URL: "https://a.example", ScrapeInterval: longInterval},
+		{Name: "b", URL: "https://b.example", ScrapeInterval: longInterval},
+	}); err != nil {
+		t.Fatalf("Sync() returned error: %v", err)
+	}
+
+	if err := p.Sync([]scraper.Scraper{
+		{Name: "a", URL: "https://a.example", ScrapeInterval: longInterval},
+	}); err != nil {
+		t.Fatalf("Sync() returned error: %v", err)
+	}
+
+	if len(p.targets) != 1 {
+		t.Fatalf("got %d targets, want 1", len(p.targets))
+	}
+	if _, ok := p.targets["b"]; ok {
+		t.Errorf("target %q should have been removed by Sync", "b")
+	}
+}
+
+func TestSync_LeavesUnchangedTargetRunning(t *testing.T) {
+	p := NewTargetPool(&scraper.GlobalConfig{}, testLogger())
+	defer stopAll(p)
+
+	s := scraper.Scraper{Name: "a", URL: "https://a.example", ScrapeInterval: longInterval}
+	if err := p.Sync([]scraper.Scraper{s}); err != nil {
+		t.Fatalf("Sync() returned error: %v", err)
+	}
+	first := p.targets["a"]
+
+	// Syncing again with byte-for-byte identical config must not restart
+	// the goroutine: the map should still hold the very same *target.
+	if err := p.Sync([]scraper.Scraper{s}); err != nil {
+		t.Fatalf("Sync() returned error: %v", err)
+	}
+	second := p.targets["a"]
+
+	if first != second {
+		t.Errorf("unchanged scraper was restarted: got a new *target")
+	}
+}
+
+func TestSync_RestartsChangedTarget(t *testing.T) {
+	p := NewTargetPool(&scraper.GlobalConfig{}, testLogger())
+	defer stopAll(p)
+
+	if err := p.Sync([]scraper.Scraper{
+		{Name: "a", URL: "https://a.example", ScrapeInterval: longInterval},
+	}); err != nil {
+		t.Fatalf("Sync() returned error: %v", err)
+	}
+	first := p.targets["a"]
+
+	if err := p.Sync([]scraper.Scraper{
+		{Name: "a", URL: "https://changed.example", ScrapeInterval: longInterval},
+	}); err != nil {
+		t.Fatalf("Sync() returned error: %v", err)
+	}
+	second := p.targets["a"]
+
+	if first == second {
+		t.Fatalf("changed scraper config did not get a new *target")
+	}
+	if first.contentHash == second.contentHash {
+		t.Errorf("new target has the same content hash as the old one")
+	}
+	select {
+	case <-first.done:
+	default:
+		t.Errorf("old target's goroutine was not stopped before Sync returned")
+	}
+}
+
+func TestJitter(t *testing.T) {
+	if got := jitter("a", 0); got != 0 {
+		t.Errorf("jitter with non-positive interval = %v, want 0", got)
+	}
+
+	const interval = 10 * time.Minute
+	got := jitter("source-a", interval)
+	if got < 0 || got >= interval {
+		t.Errorf("jitter(%q, %v) = %v, want in [0, %v)", "source-a", interval, got, interval)
+	}
+
+	// Deterministic: the same name always maps to the same offset, so
+	// restarting the pool doesn't realign a target's schedule.
+	if again := jitter("source-a", interval); got != again {
+		t.Errorf("jitter is not deterministic for the same name: %v != %v", got, again)
+	}
+
+	// Different names should land on different offsets, so targets
+	// sharing an interval don't all fire together.
+	if other := jitter("source-b", interval); other == got {
+		t.Errorf("jitter(%q, ...) and jitter(%q, ...) collided: both %v", "source-a", "source-b", got)
+	}
+}
+
+func TestContentHash(t *testing.T) {
+	a := scraper.Scraper{Name: "a", URL: "https://a.example"}
+	same := scraper.Scraper{Name: "a", URL: "https://a.example"}
+	changed := scraper.Scraper{Name: "a", URL: "https://b.example"}
+
+	hashA, err := contentHash(a)
+	if err != nil {
+		t.Fatalf("contentHash() returned error: %v", err)
+	}
+	hashSame, err := contentHash(same)
+	if err != nil {
+		t.Fatalf("contentHash() returned error: %v", err)
+	}
+	hashChanged, err := contentHash(changed)
+	if err != nil {
+		t.Fatalf("contentHash() returned error: %v", err)
+	}
+
+	if hashA != hashSame {
+		t.Errorf("identical scrapers hashed differently: %q != %q", hashA, hashSame)
+	}
+	if hashA == hashChanged {
+		t.Errorf("scrapers with different config hashed the same: %q", hashA)
+	}
+}
+
+func TestTargetPool_GlobalConfigSwap(t *testing.T) {
+	p := NewTargetPool(&scraper.GlobalConfig{UserAgent: "initial"}, testLogger())
+
+	if got := p.global.Load().UserAgent; got != "initial" {
+		t.Fatalf("global.Load().UserAgent = %q, want %q", got, "initial")
+	}
+
+	p.global.Store(&scraper.GlobalConfig{UserAgent: "updated"})
+
+	if got := p.global.Load().UserAgent; got != "updated" {
+		t.Errorf("global.Load().UserAgent = %q, want %q", got, "updated")
+	}
+}
+
+// TestTargetPool_GlobalConfigSwap_ConcurrentReads exercises the same
+// atomic.Pointer swap admin.ReloadHandler performs while a target
+// goroutine is reading it, so `go test -race` can catch a regression
+// back to the unsynchronized `*p.global = cfg.Global` this replaced.
+func TestTargetPool_GlobalConfigSwap_ConcurrentReads(t *testing.T) {
+	p := NewTargetPool(&scraper.GlobalConfig{UserAgent: "v0"}, testLogger())
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			_ = p.global.Load().UserAgent
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		p.global.Store(&scraper.GlobalConfig{UserAgent: fmt.Sprintf("v%d", i)})
+	}
+	<-done
+}
@@ -2,9 +2,11 @@ package scraper
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
-	"log"
+	stdhtml "html"
+	"log/slog"
 	"net/url"
 	"os"
 	"regexp"
@@ -15,17 +17,25 @@ import (
 	"github.com/PuerkitoBio/goquery"
 	"github.com/goodsign/monday"
 	"github.com/ilyakaznacheev/cleanenv"
+	"github.com/jakopako/goskyr/discovery"
 	"github.com/jakopako/goskyr/fetch"
+	"github.com/jakopako/goskyr/logger"
 	"github.com/jakopako/goskyr/output"
 	"github.com/jakopako/goskyr/utils"
 	"golang.org/x/net/html"
 	"gopkg.in/yaml.v2"
 )
 
+// robotsCache is shared across all scrapers so that DynamicFetcher and
+// StaticFetcher alike only fetch a given host's robots.txt once, no
+// matter how many scrapers target it.
+var robotsCache = discovery.NewRobotsCache()
+
 // GlobalConfig is used for storing global configuration parameters that
 // are needed across all scrapers
 type GlobalConfig struct {
-	UserAgent string `yaml:"user-agent"`
+	UserAgent string        `yaml:"user-agent"`
+	Logger    logger.Config `yaml:"logger,omitempty"`
 }
 
 // Config defines the overall structure of the scraper configuration.
@@ -40,9 +50,8 @@ type Config struct {
 func NewConfig(configPath string) (*Config, error) {
 	var config Config
 
-	err := cleanenv.ReadConfig(configPath, &config)
-	if err != nil {
-		log.Fatal(err)
+	if err := cleanenv.ReadConfig(configPath, &config); err != nil {
+		return nil, err
 	}
 
 	file, err := os.Open(configPath)
@@ -54,9 +63,43 @@ func NewConfig(configPath string) (*Config, error) {
 	if err := d.Decode(&config); err != nil {
 		return nil, err
 	}
+	if len(config.Writer.Columns) == 0 {
+		config.Writer.Columns = columnsFromFields(config.Scrapers)
+	}
 	return &config, nil
 }
 
+// columnsFromFields returns the union of visible Field.Names configured
+// across every scraper, in the order each name first appears, plus
+// matchesKey if any scraper has a highlighting Filter, since that's the
+// other key GetItems can add to an item. It backs the default for
+// output.WriterConfig.Columns so a csv writer's header covers every key
+// an item can have instead of just the fields of whichever item happens
+// to arrive first.
+func columnsFromFields(scrapers []Scraper) []string {
+	seen := map[string]bool{}
+	var columns []string
+	hasHighlight := false
+	for _, s := range scrapers {
+		for _, f := range s.Fields {
+			if f.Hide || seen[f.Name] {
+				continue
+			}
+			seen[f.Name] = true
+			columns = append(columns, f.Name)
+		}
+		for _, flt := range s.Filters {
+			if flt.Highlight {
+				hasHighlight = true
+			}
+		}
+	}
+	if hasHighlight {
+		columns = append(columns, matchesKey)
+	}
+	return columns
+}
+
 // RegexConfig is used for extracting a substring from a string based on the
 // given Exp and Index
 type RegexConfig struct {
@@ -112,9 +155,28 @@ type Field struct {
 
 // A Filter is used to filter certain items from the result list
 type Filter struct {
-	Field string `yaml:"field"`
-	Regex string `yaml:"regex"`
-	Match bool   `yaml:"match"`
+	Field     string `yaml:"field"`
+	Regex     string `yaml:"regex"`
+	Match     bool   `yaml:"match"`
+	Highlight bool   `yaml:"highlight,omitempty"` // wrap matches in PreTag/PostTag and record them under "_matches"
+	PreTag    string `yaml:"pre_tag,omitempty"`   // defaults to "<em>"
+	PostTag   string `yaml:"post_tag,omitempty"`  // defaults to "</em>"
+}
+
+const (
+	defaultHighlightPreTag  = "<em>"
+	defaultHighlightPostTag = "</em>"
+	matchesKey              = "_matches"
+)
+
+// MatchHighlight describes one matched substring of a filtered field,
+// mirroring the highlight DTOs used by search-result libraries such as
+// Algolia: the (tag-wrapped) value, whether the whole field matched or
+// only part of it, and the individual matched words.
+type MatchHighlight struct {
+	Value        string   `json:"value"`
+	MatchLevel   string   `json:"matchLevel"` // "full" or "partial"
+	MatchedWords []string `json:"matchedWords"`
 }
 
 // A Scraper contains all the necessary config parameters and structs needed
@@ -131,13 +193,39 @@ type Scraper struct {
 		MaxPages int             `yaml:"max_pages,omitempty"`
 	} `yaml:"paginator,omitempty"`
 	RenderJs bool `yaml:"renderJs,omitempty"`
+	// ScrapeInterval, if set, is used by scheduler.TargetPool to run this
+	// scraper periodically instead of once. A zero value means the scraper
+	// is run a single time, also when a scheduler.TargetPool starts it.
+	ScrapeInterval time.Duration `yaml:"scrape_interval,omitempty"`
+	// ScrapeTimeout bounds how long a single scrape run may take once
+	// scheduled. Defaults to ScrapeInterval when unset.
+	ScrapeTimeout time.Duration `yaml:"scrape_timeout,omitempty"`
+	// SeedSitemap, if true, additionally scrapes every URL found in the
+	// site's sitemap.xml as if it were an entry page, using the same Item
+	// and Fields config. Useful for sites that expose events as individual
+	// pages rather than through a list.
+	SeedSitemap bool `yaml:"seed_sitemap,omitempty"`
+	// SitemapSince limits sitemap seeding to URLs whose <lastmod> is more
+	// recent than the given duration, e.g. "7d". Entries without a
+	// <lastmod> are always kept. Only applies when SeedSitemap is true.
+	SitemapSince string `yaml:"sitemap_since,omitempty"`
+	// RespectRobots, if true, fetches and caches robots.txt per host and
+	// skips URLs disallowed for the configured UserAgent.
+	RespectRobots bool `yaml:"respect_robots,omitempty"`
 }
 
 // GetItems fetches and returns all items from a website according to the
-// Scraper's paramaters
-func (c Scraper) GetItems(globalConfig *GlobalConfig) ([]map[string]interface{}, error) {
+// Scraper's paramaters. Errors while extracting individual fields or items
+// are not fatal: they are reported through log with source and field
+// context and the affected item is dropped so that one broken item or
+// source doesn't tear down the whole scrape job. ctx bounds the overall
+// run: it is checked between pages and subpage fetches, and every fetch
+// itself races against ctx via fetchWithContext, so a scheduler can
+// enforce a per-scraper timeout even while a single fetch is stuck.
+func (c Scraper) GetItems(ctx context.Context, globalConfig *GlobalConfig, log *slog.Logger) ([]map[string]interface{}, error) {
 
 	var items []map[string]interface{}
+	nrDropped := 0
 
 	pageURL := c.URL
 	hasNextPage := true
@@ -153,7 +241,13 @@ func (c Scraper) GetItems(globalConfig *GlobalConfig) ([]map[string]interface{},
 		}
 	}
 	for hasNextPage {
-		res, err := fetcher.Fetch(pageURL)
+		if err := ctx.Err(); err != nil {
+			return items, err
+		}
+		if !c.fetchAllowed(ctx, fetcher, globalConfig, log, pageURL) {
+			break
+		}
+		res, err := fetchWithContext(ctx, fetcher, pageURL)
 		if err != nil {
 			return items, err
 		}
@@ -163,68 +257,9 @@ func (c Scraper) GetItems(globalConfig *GlobalConfig) ([]map[string]interface{},
 			return items, err
 		}
 
-		doc.Find(c.Item).Each(func(i int, s *goquery.Selection) {
-			for _, excludeSelector := range c.ExcludeWithSelector {
-				if s.Find(excludeSelector).Length() > 0 || s.Is(excludeSelector) {
-					return
-				}
-			}
-
-			currentItem := make(map[string]interface{})
-			for _, f := range c.Fields {
-				if f.Value != "" {
-					// add static fields
-					currentItem[f.Name] = f.Value
-				} else {
-					// handle all dynamic fields on the main page
-					if f.OnSubpage == "" {
-						err := extractField(&f, currentItem, s, pageURL)
-						if err != nil {
-							log.Printf("%s ERROR: error while parsing field %s: %v. Skipping item %v.", c.Name, f.Name, err, currentItem)
-							return
-						}
-					}
-				}
-			}
-
-			// handle all fields on subpages
-			subDocs := make(map[string]*goquery.Document)
-			for _, f := range c.Fields {
-				if f.OnSubpage != "" && f.Value == "" {
-					// check whether we fetched the page already
-					subpageURL := fmt.Sprint(currentItem[f.OnSubpage])
-					_, found := subDocs[subpageURL]
-					if !found {
-						subRes, err := fetcher.Fetch(subpageURL)
-						if err != nil {
-							log.Printf("%s ERROR: %v. Skipping item %v.", c.Name, err, currentItem)
-							return
-						}
-						subDoc, err := goquery.NewDocumentFromReader(strings.NewReader(subRes))
-						if err != nil {
-							log.Printf("%s ERROR: error while reading document: %v. Skipping item %v", c.Name, err, currentItem)
-							return
-						}
-						subDocs[subpageURL] = subDoc
-					}
-					err = extractField(&f, currentItem, subDocs[subpageURL].Selection, c.URL)
-					if err != nil {
-						log.Printf("%s ERROR: error while parsing field %s: %v. Skipping item %v.", c.Name, f.Name, err, currentItem)
-						return
-					}
-				}
-			}
-
-			// check if item should be filtered
-			filter, err := c.filterItem(currentItem)
-			if err != nil {
-				log.Fatalf("%s ERROR: error while applying filter: %v.", c.Name, err)
-			}
-			if filter {
-				currentItem = c.removeHiddenFields(currentItem)
-				items = append(items, currentItem)
-			}
-		})
+		pageItems, dropped := c.extractItemsFromDoc(ctx, fetcher, globalConfig, doc, pageURL, log)
+		items = append(items, pageItems...)
+		nrDropped += dropped
 
 		hasNextPage = false
 		pageURL = getURLString(&c.Paginator.Location, doc.Selection, pageURL)
@@ -235,15 +270,199 @@ func (c Scraper) GetItems(globalConfig *GlobalConfig) ([]map[string]interface{},
 			}
 		}
 	}
+
+	if c.SeedSitemap {
+		sitemapItems, dropped, err := c.getSitemapItems(ctx, fetcher, globalConfig, log)
+		if err != nil {
+			log.Error("error while seeding from sitemap", "source", c.Name, "error", err)
+		} else {
+			items = append(items, sitemapItems...)
+			nrDropped += dropped
+		}
+	}
+
 	// TODO: check if the dates make sense. Sometimes we have to guess the year since it
 	// does not appear on the website. In that case, eg. having a list of events around
 	// the end of one year and the beginning of the next year we might want to change the
 	// year of some events because our previous guess was rather naiv. We also might want
 	// to make this functionality optional. See issue #68
 
+	log.Info("finished scraping", "source", c.Name, "wrote", len(items), "dropped", nrDropped)
+
 	return items, nil
 }
 
+// fetchAllowed reports whether pageURL may be fetched. When RespectRobots
+// is off, or the robots check itself fails, it fails open and returns
+// true so a broken robots.txt never blocks an otherwise-working scraper.
+// ctx bounds the robots.txt fetch the same way it bounds every other
+// fetch, so a hanging robots.txt can't stall the caller indefinitely.
+func (c Scraper) fetchAllowed(ctx context.Context, fetcher fetch.Fetcher, globalConfig *GlobalConfig, log *slog.Logger, pageURL string) bool {
+	if !c.RespectRobots {
+		return true
+	}
+	ok, err := robotsCache.Allowed(ctx, fetcher, globalConfig.UserAgent, pageURL)
+	if err != nil {
+		log.Error("error while checking robots.txt, allowing fetch", "source", c.Name, "url", pageURL, "error", err)
+		return true
+	}
+	if !ok {
+		log.Info("robots-blocked", "source", c.Name, "url", pageURL, "user-agent", globalConfig.UserAgent)
+	}
+	return ok
+}
+
+// fetchWithContext runs fetcher.Fetch in its own goroutine and returns as
+// soon as either it completes or ctx is done. fetch.Fetcher has no
+// cancellation hook of its own, so a hung Fetch call can't be aborted
+// outright, but this still lets ScrapeTimeout (and a scheduler stopping a
+// target) interrupt GetItems promptly instead of waiting for that call to
+// eventually return on its own, possibly never.
+func fetchWithContext(ctx context.Context, fetcher fetch.Fetcher, pageURL string) (string, error) {
+	type result struct {
+		res string
+		err error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		res, err := fetcher.Fetch(pageURL)
+		ch <- result{res, err}
+	}()
+	select {
+	case r := <-ch:
+		return r.res, r.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// getSitemapItems seeds additional entry pages from the site's
+// sitemap.xml and extracts items from each of them exactly as if they
+// were found through the Paginator.
+func (c Scraper) getSitemapItems(ctx context.Context, fetcher fetch.Fetcher, globalConfig *GlobalConfig, log *slog.Logger) ([]map[string]interface{}, int, error) {
+	since, err := discovery.ParseSince(c.SitemapSince)
+	if err != nil {
+		return nil, 0, err
+	}
+	urls, err := discovery.SeedSitemap(ctx, fetcher, c.URL, since)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var items []map[string]interface{}
+	nrDropped := 0
+	for _, pageURL := range urls {
+		if err := ctx.Err(); err != nil {
+			return items, nrDropped, err
+		}
+		if !c.fetchAllowed(ctx, fetcher, globalConfig, log, pageURL) {
+			continue
+		}
+		res, err := fetchWithContext(ctx, fetcher, pageURL)
+		if err != nil {
+			log.Error("error while fetching sitemap url, skipping", "source", c.Name, "url", pageURL, "error", err)
+			continue
+		}
+		doc, err := goquery.NewDocumentFromReader(strings.NewReader(res))
+		if err != nil {
+			log.Error("error while reading sitemap url document, skipping", "source", c.Name, "url", pageURL, "error", err)
+			continue
+		}
+		pageItems, dropped := c.extractItemsFromDoc(ctx, fetcher, globalConfig, doc, pageURL, log)
+		items = append(items, pageItems...)
+		nrDropped += dropped
+	}
+	return items, nrDropped, nil
+}
+
+// extractItemsFromDoc runs the Item/Fields/Filters pipeline against a
+// single already-fetched page, be it a paginated list page or a page
+// seeded from the sitemap.
+func (c Scraper) extractItemsFromDoc(ctx context.Context, fetcher fetch.Fetcher, globalConfig *GlobalConfig, doc *goquery.Document, pageURL string, log *slog.Logger) ([]map[string]interface{}, int) {
+	var items []map[string]interface{}
+	nrDropped := 0
+
+	doc.Find(c.Item).Each(func(i int, s *goquery.Selection) {
+		for _, excludeSelector := range c.ExcludeWithSelector {
+			if s.Find(excludeSelector).Length() > 0 || s.Is(excludeSelector) {
+				return
+			}
+		}
+
+		currentItem := make(map[string]interface{})
+		for _, f := range c.Fields {
+			if f.Value != "" {
+				// add static fields
+				currentItem[f.Name] = f.Value
+			} else {
+				// handle all dynamic fields on the main page
+				if f.OnSubpage == "" {
+					err := extractField(&f, currentItem, s, pageURL)
+					if err != nil {
+						log.Error("error while parsing field, skipping item", "source", c.Name, "field", f.Name, "error", err, "item", currentItem)
+						nrDropped++
+						return
+					}
+				}
+			}
+		}
+
+		// handle all fields on subpages
+		subDocs := make(map[string]*goquery.Document)
+		for _, f := range c.Fields {
+			if f.OnSubpage != "" && f.Value == "" {
+				// check whether we fetched the page already
+				subpageURL := fmt.Sprint(currentItem[f.OnSubpage])
+				_, found := subDocs[subpageURL]
+				if !found {
+					if err := ctx.Err(); err != nil {
+						log.Error("context cancelled while fetching subpage, skipping item", "source", c.Name, "url", subpageURL, "error", err, "item", currentItem)
+						nrDropped++
+						return
+					}
+					if !c.fetchAllowed(ctx, fetcher, globalConfig, log, subpageURL) {
+						nrDropped++
+						return
+					}
+					subRes, err := fetchWithContext(ctx, fetcher, subpageURL)
+					if err != nil {
+						log.Error("error while fetching subpage, skipping item", "source", c.Name, "url", subpageURL, "error", err, "item", currentItem)
+						nrDropped++
+						return
+					}
+					subDoc, err := goquery.NewDocumentFromReader(strings.NewReader(subRes))
+					if err != nil {
+						log.Error("error while reading subpage document, skipping item", "source", c.Name, "url", subpageURL, "error", err, "item", currentItem)
+						nrDropped++
+						return
+					}
+					subDocs[subpageURL] = subDoc
+				}
+				err := extractField(&f, currentItem, subDocs[subpageURL].Selection, c.URL)
+				if err != nil {
+					log.Error("error while parsing field, skipping item", "source", c.Name, "field", f.Name, "error", err, "item", currentItem)
+					nrDropped++
+					return
+				}
+			}
+		}
+
+		// check if item should be filtered
+		filter, err := c.filterItem(currentItem)
+		if err != nil {
+			log.Error("error while applying filter, skipping item", "source", c.Name, "error", err, "item", currentItem)
+			nrDropped++
+			return
+		}
+		if filter {
+			currentItem = c.removeHiddenFields(currentItem)
+			items = append(items, currentItem)
+		}
+	})
+
+	return items, nrDropped
+}
+
 func (c *Scraper) filterItem(item map[string]interface{}) (bool, error) {
 	nrMatchTrue := 0
 	filterMatchTrue := false
@@ -253,18 +472,26 @@ func (c *Scraper) filterItem(item map[string]interface{}) (bool, error) {
 		if err != nil {
 			return false, err
 		}
-		if fieldValue, found := item[filter.Field]; found {
-			if filter.Match {
-				nrMatchTrue++
-				if regex.MatchString(fmt.Sprint(fieldValue)) {
-					filterMatchTrue = true
-				}
-			} else {
-				if regex.MatchString(fmt.Sprint(fieldValue)) {
-					filterMatchFalse = false
-				}
+		fieldValue, found := item[filter.Field]
+		if !found {
+			continue
+		}
+		valueStr := fmt.Sprint(fieldValue)
+		indices := regex.FindAllStringIndex(valueStr, -1)
+		matched := len(indices) > 0
+		if filter.Match {
+			nrMatchTrue++
+			if matched {
+				filterMatchTrue = true
+			}
+		} else {
+			if matched {
+				filterMatchFalse = false
 			}
 		}
+		if filter.Highlight && matched {
+			addMatchHighlight(item, &filter, valueStr, indices)
+		}
 	}
 	if nrMatchTrue == 0 {
 		filterMatchTrue = true
@@ -272,10 +499,76 @@ func (c *Scraper) filterItem(item map[string]interface{}) (bool, error) {
 	return filterMatchTrue && filterMatchFalse, nil
 }
 
+// addMatchHighlight records the substrings filter's regex matched in
+// valueStr under item[matchesKey][filter.Field], wrapping the stored
+// value in filter.PreTag/PostTag so a consuming UI can render match
+// context. The surrounding and matched text is HTML-escaped before being
+// wrapped so that scraped content containing "<", ">" or "&" can't inject
+// markup of its own into the highlighted Value; PreTag/PostTag themselves
+// are trusted config and are written out verbatim. indices are byte
+// offsets as returned by regexp.FindAllStringIndex, which always fall on
+// rune boundaries, so slicing valueStr directly is safe even for
+// multi-byte runes.
+func addMatchHighlight(item map[string]interface{}, filter *Filter, valueStr string, indices [][]int) {
+	matches, _ := item[matchesKey].(map[string][]MatchHighlight)
+	if matches == nil {
+		matches = map[string][]MatchHighlight{}
+	}
+
+	preTag := filter.PreTag
+	if preTag == "" {
+		preTag = defaultHighlightPreTag
+	}
+	postTag := filter.PostTag
+	if postTag == "" {
+		postTag = defaultHighlightPostTag
+	}
+
+	matchedWords := make([]string, len(indices))
+	fullMatch := false
+	var b strings.Builder
+	last := 0
+	for i, idx := range indices {
+		matchedWords[i] = valueStr[idx[0]:idx[1]]
+		if idx[0] == 0 && idx[1] == len(valueStr) {
+			fullMatch = true
+		}
+		b.WriteString(stdhtml.EscapeString(valueStr[last:idx[0]]))
+		b.WriteString(preTag)
+		b.WriteString(stdhtml.EscapeString(valueStr[idx[0]:idx[1]]))
+		b.WriteString(postTag)
+		last = idx[1]
+	}
+	b.WriteString(stdhtml.EscapeString(valueStr[last:]))
+
+	matchLevel := "partial"
+	if fullMatch {
+		matchLevel = "full"
+	}
+
+	matches[filter.Field] = append(matches[filter.Field], MatchHighlight{
+		Value:        b.String(),
+		MatchLevel:   matchLevel,
+		MatchedWords: matchedWords,
+	})
+	item[matchesKey] = matches
+}
+
 func (c *Scraper) removeHiddenFields(item map[string]interface{}) map[string]interface{} {
+	matches, hasMatches := item[matchesKey].(map[string][]MatchHighlight)
 	for _, f := range c.Fields {
 		if f.Hide {
 			delete(item, f.Name)
+			if hasMatches {
+				delete(matches, f.Name)
+			}
+		}
+	}
+	if hasMatches {
+		if len(matches) == 0 {
+			delete(item, matchesKey)
+		} else {
+			item[matchesKey] = matches
 		}
 	}
 	return item
@@ -0,0 +1,104 @@
+package scraper
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFilterItem_Highlight(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		filters []Filter
+		want    map[string][]MatchHighlight
+	}{
+		{
+			name:  "partial match",
+			value: "live jazz tonight",
+			filters: []Filter{
+				{Field: "title", Regex: "jazz", Match: true, Highlight: true},
+			},
+			want: map[string][]MatchHighlight{
+				"title": {
+					{Value: "live <em>jazz</em> tonight", MatchLevel: "partial", MatchedWords: []string{"jazz"}},
+				},
+			},
+		},
+		{
+			name:  "match spanning the entire field is a full match",
+			value: "jazz",
+			filters: []Filter{
+				{Field: "title", Regex: "jazz", Match: true, Highlight: true},
+			},
+			want: map[string][]MatchHighlight{
+				"title": {
+					{Value: "<em>jazz</em>", MatchLevel: "full", MatchedWords: []string{"jazz"}},
+				},
+			},
+		},
+		{
+			name:  "multi-byte runes around the match are preserved",
+			value: "Café ☕ jazz night",
+			filters: []Filter{
+				{Field: "title", Regex: "jazz", Match: true, Highlight: true},
+			},
+			want: map[string][]MatchHighlight{
+				"title": {
+					{Value: "Café ☕ <em>jazz</em> night", MatchLevel: "partial", MatchedWords: []string{"jazz"}},
+				},
+			},
+		},
+		{
+			name:  "repeated matches in one field are all recorded",
+			value: "jazz and more jazz",
+			filters: []Filter{
+				{Field: "title", Regex: "jazz", Match: true, Highlight: true},
+			},
+			want: map[string][]MatchHighlight{
+				"title": {
+					{Value: "<em>jazz</em> and more <em>jazz</em>", MatchLevel: "partial", MatchedWords: []string{"jazz", "jazz"}},
+				},
+			},
+		},
+		{
+			name:  "two filters matching overlapping substrings of the same field each append their own entry",
+			value: "live jazz night",
+			filters: []Filter{
+				{Field: "title", Regex: "jazz", Match: true, Highlight: true},
+				{Field: "title", Regex: "jazz night", Match: true, Highlight: true},
+			},
+			want: map[string][]MatchHighlight{
+				"title": {
+					{Value: "live <em>jazz</em> night", MatchLevel: "partial", MatchedWords: []string{"jazz"}},
+					{Value: "live <em>jazz night</em>", MatchLevel: "partial", MatchedWords: []string{"jazz night"}},
+				},
+			},
+		},
+		{
+			name:  "custom pre/post tags and HTML-unsafe scraped content are escaped",
+			value: "<b>breaking</b>: jazz & blues",
+			filters: []Filter{
+				{Field: "title", Regex: "jazz", Match: true, Highlight: true, PreTag: "[[", PostTag: "]]"},
+			},
+			want: map[string][]MatchHighlight{
+				"title": {
+					{Value: "&lt;b&gt;breaking&lt;/b&gt;: [[jazz]] &amp; blues", MatchLevel: "partial", MatchedWords: []string{"jazz"}},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Scraper{Filters: tt.filters}
+			item := map[string]interface{}{"title": tt.value}
+			if _, err := c.filterItem(item); err != nil {
+				t.Fatalf("filterItem() returned error: %v", err)
+			}
+			got, _ := item[matchesKey].(map[string][]MatchHighlight)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("item[%q] = %#v, want %#v", matchesKey, got, tt.want)
+			}
+		})
+	}
+}